@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+)
+
+// selectPreferredAddress orders candidates following a simplified RFC 6724
+// destination address selection: addresses sharing scope (IPv4 vs IPv6) with
+// one of this host's local source addresses are preferred, and ties are
+// broken by the longest matching prefix against the best-matching local
+// address. It returns the address a real client talking to this host would
+// likely pick first, together with the full candidate list in that order.
+func selectPreferredAddress(candidates []net.IP) (net.IP, []net.IP, error) {
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("no candidate addresses")
+	}
+	if len(candidates) == 1 {
+		return candidates[0], candidates, nil
+	}
+
+	localAddrs, err := localSourceAddresses()
+	if err != nil || len(localAddrs) == 0 {
+		return candidates[0], candidates, nil
+	}
+
+	ordered := make([]net.IP, len(candidates))
+	copy(ordered, candidates)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return addressRank(ordered[i], localAddrs) > addressRank(ordered[j], localAddrs)
+	})
+
+	return ordered[0], ordered, nil
+}
+
+// addressRank scores ip against the set of local source addresses. A
+// scope match (same address family) always outranks a same-family prefix
+// tie against an address of a different family; within a matching scope,
+// the longest common prefix with any local address wins.
+func addressRank(ip net.IP, localAddrs []net.IP) int {
+	best := -1
+	for _, local := range localAddrs {
+		if !sameScope(ip, local) {
+			continue
+		}
+		if l := commonPrefixLen(ip, local); l > best {
+			best = l
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return 1000 + best
+}
+
+// sameScope reports whether ip and local belong to the same address family
+// (IPv4 vs IPv6), the scope distinction net.InterfaceAddrs lets us observe
+// without a full routing-table lookup.
+func sameScope(ip, local net.IP) bool {
+	return (ip.To4() != nil) == (local.To4() != nil)
+}
+
+// commonPrefixLen returns the number of leading bits shared between a and b,
+// comparing their 16-byte representations so IPv4 and IPv6 addresses of
+// matching scope compare consistently.
+func commonPrefixLen(a, b net.IP) int {
+	a16, b16 := a.To16(), b.To16()
+	if a16 == nil || b16 == nil {
+		return 0
+	}
+
+	bits := 0
+	for i := 0; i < len(a16); i++ {
+		x := a16[i] ^ b16[i]
+		if x == 0 {
+			bits += 8
+			continue
+		}
+		for x&0x80 == 0 {
+			bits++
+			x <<= 1
+		}
+		break
+	}
+	return bits
+}
+
+// localSourceAddresses returns the non-loopback IP addresses configured on
+// this host's interfaces, used as the candidate source addresses for
+// destination address selection.
+func localSourceAddresses() ([]net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		ips = append(ips, ipNet.IP)
+	}
+	return ips, nil
+}
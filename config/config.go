@@ -0,0 +1,180 @@
+// Package config loads the probe configuration for webServerCheck from a
+// YAML (or JSON) file. It replaces the compile-time constants that used to
+// live in main.go (expected upstream IPs, URIs, worker count, ...) so the
+// tool can be pointed at a new webserver without editing and rebuilding it.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Probe describes a single HTTP(S) request that should be performed
+// against every domain that passed the upstream-IP check.
+type Probe struct {
+	// Name identifies the probe in log messages and error codes.
+	Name string `yaml:"name" json:"name"`
+
+	// Method is the HTTP method to use, defaulting to GET.
+	Method string `yaml:"method" json:"method"`
+
+	// Path is appended to the domain to build the request URL, e.g. "/health".
+	Path string `yaml:"path" json:"path"`
+
+	// Host, if set, overrides the Host header sent with the request.
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+
+	// ExpectStatus lists the HTTP status codes considered successful.
+	// Defaults to []int{200} when empty.
+	ExpectStatus []int `yaml:"expect_status" json:"expect_status"`
+
+	// BodyMatch, if set, must be found in the response body for the probe
+	// to succeed. Treated as a substring unless BodyRegex is true.
+	BodyMatch string `yaml:"body_match,omitempty" json:"body_match,omitempty"`
+
+	// BodyRegex treats BodyMatch as a regular expression instead of a
+	// plain substring.
+	BodyRegex bool `yaml:"body_regex,omitempty" json:"body_regex,omitempty"`
+
+	// TimeoutSeconds is the per-request timeout, defaulting to 5 seconds.
+	TimeoutSeconds int `yaml:"timeout_seconds" json:"timeout_seconds"`
+
+	// Scheme is "http", "https", or "both", defaulting to "http". A probe
+	// configured with "both" is expanded into one http and one https probe
+	// by Load.
+	Scheme string `yaml:"scheme" json:"scheme"`
+}
+
+// Config is the root object of probes.yaml (or probes.json).
+type Config struct {
+	// ExpectedIPs is the allowlist of upstream addresses a domain must
+	// resolve to in order to be probed.
+	ExpectedIPs []string `yaml:"expected_ips" json:"expected_ips"`
+
+	// Probes is the set of requests performed against every domain.
+	Probes []Probe `yaml:"probes" json:"probes"`
+
+	// Workers sets the size of the domainWorker pool, defaulting to 10.
+	Workers int `yaml:"workers" json:"workers"`
+
+	// DNSTimeoutSeconds bounds how long a single domain's A/AAAA lookup may
+	// take, defaulting to 5 seconds.
+	DNSTimeoutSeconds int `yaml:"dns_timeout_seconds" json:"dns_timeout_seconds"`
+
+	// DomainBudgetSeconds bounds the DNS lookup and every probe for a single
+	// domain together, defaulting to 30 seconds. It's the ceiling a slow DNS
+	// server or a stalled probe can't push past, independent of the smaller
+	// per-lookup/per-probe timeouts above.
+	DomainBudgetSeconds int `yaml:"domain_budget_seconds" json:"domain_budget_seconds"`
+
+	// TLSInsecureSkipVerify disables certificate verification for https
+	// probes. Defaults to false; only meant for testing self-signed vhosts.
+	TLSInsecureSkipVerify bool `yaml:"tls_insecure_skip_verify" json:"tls_insecure_skip_verify"`
+
+	// CertExpiryWarningDays is the window, in days, within which an https
+	// probe's leaf certificate triggers a w01 warning. Defaults to 14.
+	CertExpiryWarningDays int `yaml:"cert_expiry_warning_days" json:"cert_expiry_warning_days"`
+
+	// ReportFormat selects the Reporter implementation: "text" (the
+	// original plain-text log files), "json", or "prometheus". Defaults to
+	// "text".
+	ReportFormat string `yaml:"report_format" json:"report_format"`
+}
+
+// Load reads and parses the configuration file at path. Both YAML and JSON
+// are accepted; the format is chosen from the file extension, falling back
+// to YAML for anything that isn't ".json".
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, cfg)
+	} else {
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if err := cfg.applyDefaultsAndValidate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyDefaultsAndValidate fills in default values for optional fields and
+// rejects configurations that would leave the checker with nothing to do.
+func (c *Config) applyDefaultsAndValidate() error {
+	if len(c.ExpectedIPs) == 0 {
+		return fmt.Errorf("config: expected_ips must list at least one upstream address")
+	}
+	if len(c.Probes) == 0 {
+		return fmt.Errorf("config: probes must list at least one probe")
+	}
+	if c.Workers <= 0 {
+		c.Workers = 10
+	}
+	if c.DNSTimeoutSeconds <= 0 {
+		c.DNSTimeoutSeconds = 5
+	}
+	if c.DomainBudgetSeconds <= 0 {
+		c.DomainBudgetSeconds = 30
+	}
+	if c.CertExpiryWarningDays <= 0 {
+		c.CertExpiryWarningDays = 14
+	}
+	if c.ReportFormat == "" {
+		c.ReportFormat = "text"
+	}
+	switch c.ReportFormat {
+	case "text", "json", "prometheus":
+	default:
+		return fmt.Errorf("config: report_format %q must be text, json, or prometheus", c.ReportFormat)
+	}
+
+	var expanded []Probe
+	for i := range c.Probes {
+		p := c.Probes[i]
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("probe%d", i+1)
+		}
+		if p.Method == "" {
+			p.Method = "GET"
+		}
+		if p.TimeoutSeconds <= 0 {
+			p.TimeoutSeconds = 5
+		}
+		if len(p.ExpectStatus) == 0 {
+			p.ExpectStatus = []int{200}
+		}
+		if p.Scheme == "" {
+			p.Scheme = "http"
+		}
+
+		switch p.Scheme {
+		case "http", "https":
+			expanded = append(expanded, p)
+		case "both":
+			httpProbe, httpsProbe := p, p
+			httpProbe.Scheme, httpProbe.Name = "http", p.Name+"-http"
+			httpsProbe.Scheme, httpsProbe.Name = "https", p.Name+"-https"
+			expanded = append(expanded, httpProbe, httpsProbe)
+		default:
+			return fmt.Errorf("config: probe %q has invalid scheme %q (must be http, https, or both)", p.Name, p.Scheme)
+		}
+	}
+	c.Probes = expanded
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import "testing"
+
+func TestApplyDefaultsAndValidateDefaults(t *testing.T) {
+	cfg := &Config{
+		ExpectedIPs: []string{"203.0.113.10"},
+		Probes:      []Probe{{}},
+	}
+
+	if err := cfg.applyDefaultsAndValidate(); err != nil {
+		t.Fatalf("applyDefaultsAndValidate returned error: %v", err)
+	}
+
+	if cfg.Workers != 10 {
+		t.Errorf("Workers = %d, want default 10", cfg.Workers)
+	}
+	if cfg.DNSTimeoutSeconds != 5 {
+		t.Errorf("DNSTimeoutSeconds = %d, want default 5", cfg.DNSTimeoutSeconds)
+	}
+	if cfg.DomainBudgetSeconds != 30 {
+		t.Errorf("DomainBudgetSeconds = %d, want default 30", cfg.DomainBudgetSeconds)
+	}
+	if cfg.CertExpiryWarningDays != 14 {
+		t.Errorf("CertExpiryWarningDays = %d, want default 14", cfg.CertExpiryWarningDays)
+	}
+	if cfg.ReportFormat != "text" {
+		t.Errorf("ReportFormat = %q, want default \"text\"", cfg.ReportFormat)
+	}
+
+	if len(cfg.Probes) != 1 {
+		t.Fatalf("Probes = %d entries, want 1", len(cfg.Probes))
+	}
+	p := cfg.Probes[0]
+	if p.Name != "probe1" {
+		t.Errorf("Probe.Name = %q, want default \"probe1\"", p.Name)
+	}
+	if p.Method != "GET" {
+		t.Errorf("Probe.Method = %q, want default \"GET\"", p.Method)
+	}
+	if p.TimeoutSeconds != 5 {
+		t.Errorf("Probe.TimeoutSeconds = %d, want default 5", p.TimeoutSeconds)
+	}
+	if p.Scheme != "http" {
+		t.Errorf("Probe.Scheme = %q, want default \"http\"", p.Scheme)
+	}
+	if len(p.ExpectStatus) != 1 || p.ExpectStatus[0] != 200 {
+		t.Errorf("Probe.ExpectStatus = %v, want [200]", p.ExpectStatus)
+	}
+}
+
+func TestApplyDefaultsAndValidateBothSchemeExpansion(t *testing.T) {
+	cfg := &Config{
+		ExpectedIPs: []string{"203.0.113.10"},
+		Probes:      []Probe{{Name: "health", Path: "/health", Scheme: "both"}},
+	}
+
+	if err := cfg.applyDefaultsAndValidate(); err != nil {
+		t.Fatalf("applyDefaultsAndValidate returned error: %v", err)
+	}
+
+	if len(cfg.Probes) != 2 {
+		t.Fatalf("Probes = %d entries, want 2 after \"both\" expansion", len(cfg.Probes))
+	}
+	if got, want := cfg.Probes[0].Scheme, "http"; got != want {
+		t.Errorf("Probes[0].Scheme = %q, want %q", got, want)
+	}
+	if got, want := cfg.Probes[0].Name, "health-http"; got != want {
+		t.Errorf("Probes[0].Name = %q, want %q", got, want)
+	}
+	if got, want := cfg.Probes[1].Scheme, "https"; got != want {
+		t.Errorf("Probes[1].Scheme = %q, want %q", got, want)
+	}
+	if got, want := cfg.Probes[1].Name, "health-https"; got != want {
+		t.Errorf("Probes[1].Name = %q, want %q", got, want)
+	}
+}
+
+func TestApplyDefaultsAndValidateRejectsMissingExpectedIPs(t *testing.T) {
+	cfg := &Config{Probes: []Probe{{}}}
+
+	if err := cfg.applyDefaultsAndValidate(); err == nil {
+		t.Fatal("applyDefaultsAndValidate returned nil error, want one for missing expected_ips")
+	}
+}
+
+func TestApplyDefaultsAndValidateRejectsMissingProbes(t *testing.T) {
+	cfg := &Config{ExpectedIPs: []string{"203.0.113.10"}}
+
+	if err := cfg.applyDefaultsAndValidate(); err == nil {
+		t.Fatal("applyDefaultsAndValidate returned nil error, want one for missing probes")
+	}
+}
+
+func TestApplyDefaultsAndValidateRejectsInvalidScheme(t *testing.T) {
+	cfg := &Config{
+		ExpectedIPs: []string{"203.0.113.10"},
+		Probes:      []Probe{{Scheme: "ftp"}},
+	}
+
+	if err := cfg.applyDefaultsAndValidate(); err == nil {
+		t.Fatal("applyDefaultsAndValidate returned nil error, want one for invalid scheme")
+	}
+}
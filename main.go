@@ -1,292 +1,455 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"crypto/x509"
+	"flag"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/Jukla/webServerCheck/config"
+	"github.com/Jukla/webServerCheck/nginxconf"
+	"github.com/Jukla/webServerCheck/reporter"
 )
 
 const (
-	numberOfWorker = 10
-
-	// regex for parsing nginx.conf for domains
-	re_domain = `^\s+server_name\s+([a-zA-z0-9._-]+);$`
-
-	// we test against specific webservers. Only domains will be tested which
-	// resolves to one of these
-	expectedIPOfWebserver1 = "x.x.x.x."
-	expectedIPOfWebserver2 = "x.x.x.x."
-
-	// we test two uri against a domain.
-	uri1 = "enterURI1"
-	uri2 = "enterURI2"
-
 	// format of prefixed timestamp in log messages
 	timeLogFormat = "2006-01-02 15:04:05"
 
 	// format of timestamp in log file names
 	timeFileFormat = "20060102-1504"
 
-	// Used in testUriAvailability for custom http.Client
-	httpClientTimeout = 5
+	// process exit codes
+	exitOK        = 0
+	exitErrors    = 1
+	exitCancelled = 2
+
+	// probeErrorCodeBase is the first error code number available to the
+	// per-probe codes derived in testUriAvailability (e10, e11, ...). It
+	// leaves e01-e09 for the fixed codes used by domainWorker and
+	// testUriAvailability (DNS, no probe for the scheme, upstream allowlist
+	// and address selection, TLS verification) so a config with many probes
+	// can never collide with them.
+	probeErrorCodeBase = 10
 )
 
-// Call this program with a configuration file of a webserver as it's one and only argument.
-// You can feed this program with different config files, but you have to edit the constant re_domain.
-// Output will be written into two files in the same directory where program is located. Log files
-// will have a timestamp in its name as defined by constant timeFileFormat.
+// target is a single (host, port, scheme) combination derived from an nginx
+// server block's server_name and listen directives.
+type target struct {
+	Host   string
+	Port   int
+	Scheme string
+}
+
+// Call this program with a config file describing the expected webservers and
+// probes (-config probes.yaml) and, as its one and only positional argument, the
+// nginx configuration file to read vhosts from. Output is written through the
+// Reporter selected by cfg.ReportFormat; text and json reporters name their
+// files with a timestamp as defined by constant timeFileFormat.
 func main() {
 
+	configPath := flag.String("config", "", "path to probe configuration file (YAML or JSON)")
+	flag.Parse()
+
 	ex, err := os.Executable()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error determining absolute path of executable.\n")
 		os.Exit(1)
 	}
 
-	if len(os.Args) != 2 {
-		fmt.Printf("Usage: %s [config file]\n", ex)
+	if *configPath == "" || flag.NArg() != 1 {
+		fmt.Printf("Usage: %s -config probes.yaml [nginx config file]\n", ex)
 		os.Exit(0)
 	}
 
-	execDir := filepath.Dir(ex)
-
-	// we count domainWorker goroutines
-	var wg sync.WaitGroup
-
-	// every error message gets counted
-	var errCount uint16
-
-	// domainC gets read by domainWorker
-	domainC := make(chan string)
-
-	// status messages and listing of working URLs
-	logC := make(chan string)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config file %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
 
-	// error log messages (failing URLs)
-	errC := make(chan string)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// logFinishedC channel signals that every log message was processed and cleanups were done
-	logFinishedC := make(chan struct{})
-	// errLogFinishedC channel signals that every error message was processed and cleanups were done
-	errLogFinishedC := make(chan struct{})
+	execDir := filepath.Dir(ex)
 
 	start := time.Now()
-
 	fileFormatStartTime := start.Format(timeFileFormat)
 
-	errorLogFile := fmt.Sprintf("%s/error.%s.log", execDir, fileFormatStartTime)
-	go handleErrorLog(&errorLogFile, &errCount, errC, errLogFinishedC)
+	rep, err := newReporter(cfg, execDir, fileFormatStartTime)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing reporter: %v\n", err)
+		os.Exit(1)
+	}
+
+	// we count domainWorker goroutines
+	var wg sync.WaitGroup
 
-	mainLogFileFile := fmt.Sprintf("%s/main.%s.log", execDir, fileFormatStartTime)
-	go handleMainLog(&mainLogFileFile, logC, logFinishedC)
+	// okCount and errCount are accumulated explicitly here, rather than inferred
+	// from the number of lines a reporter happened to write.
+	var okCount, errCount uint64
 
-	logC <- fmt.Sprintf("%s --> Starting\n", start.Format(timeLogFormat))
+	// domainC gets read by domainWorker
+	domainC := make(chan target)
 
-	wg.Add(numberOfWorker)
-	for i := 0; i < numberOfWorker; i++ {
-		go domainWorker(domainC, logC, errC, &wg)
+	wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go domainWorker(ctx, cfg, domainC, rep, &okCount, &errCount, &wg)
 	}
 
-	foundDomains := parseDomainsFromConf()
-	for domain := range foundDomains {
-		domainC <- domain
+	foundTargets, err := parseTargetsFromConf(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing nginx config %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
 	}
 
+feed:
+	for _, t := range foundTargets {
+		select {
+		case domainC <- t:
+		case <-ctx.Done():
+			break feed
+		}
+	}
 	close(domainC)
 
 	wg.Wait()
 
-	// close channel and block until all error log messages were processed
-	close(errC)
-	<-errLogFinishedC
-
-	elapsed := time.Since(start)
-
-	logC <- fmt.Sprintf("%s --> Finished\n", time.Now().Format(timeLogFormat))
-	logC <- fmt.Sprintf("\t\t    --> Duration: %s\n", elapsed)
-	logC <- fmt.Sprintf("\t\t    --> Domains:  %d\n", len(foundDomains))
-	logC <- fmt.Sprintf("\t\t    --> Error:\t  %d\n", errCount)
+	summary := reporter.Summary{
+		Domains:  len(foundTargets),
+		OK:       int(atomic.LoadUint64(&okCount)),
+		Errors:   int(atomic.LoadUint64(&errCount)),
+		Duration: time.Since(start),
+	}
 
-	// close channel and block until all normal log messages were processed
-	close(logC)
-	<-logFinishedC
+	if err := rep.Finish(summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error finishing report: %v\n", err)
+		os.Exit(1)
+	}
 
-	if errCount == 0 {
-		os.Exit(0)
+	if ctx.Err() != nil {
+		os.Exit(exitCancelled)
+	}
+	if summary.Errors == 0 {
+		os.Exit(exitOK)
 	} else {
-		os.Exit(1)
+		os.Exit(exitErrors)
 	}
 }
 
-// parseDomainsFromConf returns a map with valid domains as its keys from the config file
-// which was provided as argument due program call. re_domain is used to find valid domains.
-// The value of every key in the returned map is always an empty struct.
-func parseDomainsFromConf() map[string]struct{} {
-
-	domains := make(map[string]struct{})
+// newReporter builds the Reporter selected by cfg.ReportFormat, rooted at execDir
+// and, for the text and json reporters, named with startTime.
+func newReporter(cfg *config.Config, execDir, startTime string) (reporter.Reporter, error) {
+	switch cfg.ReportFormat {
+	case "json":
+		return reporter.NewJSONReporter(fmt.Sprintf("%s/events.%s.jsonl", execDir, startTime))
+	case "prometheus":
+		return reporter.NewPrometheusReporter(fmt.Sprintf("%s/webserver_probe.prom", execDir)), nil
+	default:
+		return reporter.NewTextReporter(
+			fmt.Sprintf("%s/main.%s.log", execDir, startTime),
+			fmt.Sprintf("%s/error.%s.log", execDir, startTime),
+		)
+	}
+}
 
-	f, err := os.Open(os.Args[1])
+// parseTargetsFromConf parses the nginx configuration at path into one target per
+// (server_name, listen) combination, deduplicated, so each vhost is probed on the
+// port and scheme nginx actually binds it to instead of assuming port 80.
+func parseTargetsFromConf(path string) ([]target, error) {
+	servers, err := nginxconf.Parse(path)
 	if err != nil {
-		panic(fmt.Sprintf("Error opening provided config file %s.\n", os.Args[1]))
+		return nil, err
 	}
-	defer f.Close()
-
-	re := regexp.MustCompile(re_domain)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		matchedLines := re.FindStringSubmatch(line)
-		if len(matchedLines) == 2 {
-			domains[matchedLines[1]] = struct{}{}
+
+	seen := make(map[target]struct{})
+	var targets []target
+
+	for _, srv := range servers {
+		listens := srv.Listens
+		if len(listens) == 0 {
+			listens = []nginxconf.Listen{{Port: 80}}
+		}
+
+		for _, name := range srv.Names {
+			for _, listen := range listens {
+				scheme := "http"
+				if listen.SSL {
+					scheme = "https"
+				}
+
+				t := target{Host: name, Port: listen.Port, Scheme: scheme}
+				if _, ok := seen[t]; ok {
+					continue
+				}
+				seen[t] = struct{}{}
+				targets = append(targets, t)
+			}
 		}
 	}
 
-	return domains
+	return targets, nil
 }
 
-// domainWorker fetches domains from domainC and performs basic domain tests before giving the domain
-// to testUriAvailability which performs deeper url checks on potential domains
-func domainWorker(domainC <-chan string, logC chan<- string, errC chan<- string, wg *sync.WaitGroup) {
+// domainWorker fetches targets from domainC and performs basic domain tests before giving the
+// target to testUriAvailability which performs deeper url checks on potential domains. Once ctx
+// is cancelled, the worker keeps draining domainC (so the feed loop in main and close(domainC)
+// don't deadlock) but stops dispatching new DNS lookups or probes, letting whatever is already
+// in flight finish or hit its own timeout.
+func domainWorker(ctx context.Context, cfg *config.Config, domainC <-chan target, rep reporter.Reporter, okCount, errCount *uint64, wg *sync.WaitGroup) {
 
-	for domain := range domainC {
-		IPaddresses, err := net.LookupHost(domain)
-		if err != nil {
-			errC <- fmt.Sprintf("%s '%s': (e01) Problem getting A records: %v\n", time.Now().Format(timeLogFormat), domain, IPaddresses)
+	// Built once and reused for every domain and probe this worker handles.
+	httpClient := newHTTPClient()
+	httpsClient := newHTTPSClient(cfg.TLSInsecureSkipVerify)
+
+	for t := range domainC {
+		select {
+		case <-ctx.Done():
 			continue
+		default:
 		}
 
-		if len(IPaddresses) != 1 {
-			errC <- fmt.Sprintf("%s '%s': (e02) Got more than one returned IP address : %v\n", time.Now().Format(timeLogFormat), domain, IPaddresses)
+		label := fmt.Sprintf("%s:%d", t.Host, t.Port)
+
+		// domainCtx bounds the DNS lookup and every probe for this target together,
+		// instead of each having its own independent timeout.
+		domainCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.DomainBudgetSeconds)*time.Second)
+
+		dnsCtx, dnsCancel := context.WithTimeout(domainCtx, time.Duration(cfg.DNSTimeoutSeconds)*time.Second)
+		ipAddrs, err := net.DefaultResolver.LookupIPAddr(dnsCtx, t.Host)
+		dnsCancel()
+		if err != nil {
+			cancel()
+			atomic.AddUint64(errCount, 1)
+			rep.DomainFailed(label, "", "e01", fmt.Errorf("problem getting A/AAAA records: %w", err))
 			continue
 		}
 
-		if IPaddresses[0] == expectedIPOfWebserver1 || IPaddresses[0] == expectedIPOfWebserver2 {
-			if ok := testUriAvailability(&domain, errC, wg); ok {
-				logC <- fmt.Sprintf("%s '%s': ok\n", time.Now().Format(timeLogFormat), domain)
+		var candidates []net.IP
+		for _, addr := range ipAddrs {
+			if isExpectedIP(addr.IP.String(), cfg.ExpectedIPs) {
+				candidates = append(candidates, addr.IP)
 			}
-		} else {
-			errC <- fmt.Sprintf("%s '%s': (e03) A record resolves to wrong IP address: %v\n", time.Now().Format(timeLogFormat), domain, IPaddresses[0])
 		}
 
-	}
-	
-	wg.Done()	
-}
-
-// testUriAvailability tests the availability concurrently of uri1 and uri2 and returns true if both tests
-// againts the uri's are successful. One domain can generate two (uri1+uri2) error log messages.
-func testUriAvailability(domain *string, errC chan<- string, wg *sync.WaitGroup) bool {
+		if len(candidates) == 0 {
+			cancel()
+			atomic.AddUint64(errCount, 1)
+			rep.DomainFailed(label, "", "e03", fmt.Errorf("none of the resolved addresses matched the upstream allowlist: %v", ipAddrs))
+			continue
+		}
 
-	// for concatenating both complete urls
-	var buffer bytes.Buffer
+		selected, _, err := selectPreferredAddress(candidates)
+		if err != nil {
+			cancel()
+			atomic.AddUint64(errCount, 1)
+			rep.DomainFailed(label, "", "e03", err)
+			continue
+		}
 
-	// Result gets passed throug Channel uri1C and uri2C
-	type Result struct {
-		Message *http.Response
-		Error   error
+		results, ok := testUriAvailability(domainCtx, cfg, t, selected, httpClient, httpsClient, rep, errCount)
+		cancel()
+		if ok {
+			atomic.AddUint64(okCount, 1)
+			rep.DomainOK(label, results)
+		}
 	}
 
-	// function will block until each goroutines pass their result
-	// through its channel
-	uri1C := make(chan Result)
-	uri2C := make(chan Result)
-
-	// custom http.Client with shorter timeout
-	cusHttpClient := &http.Client{
-		Timeout: time.Duration(httpClientTimeout * time.Second),
-		// if we don't want to be redirected
-		//CheckRedirect: func(req *http.Request, via []*http.Request) error {
-		//	return fmt.Errorf("Redirected!")
-		//},
+	wg.Done()
+}
+
+// isExpectedIP reports whether ip is contained in expectedIPs.
+func isExpectedIP(ip string, expectedIPs []string) bool {
+	for _, expected := range expectedIPs {
+		if ip == expected {
+			return true
+		}
 	}
+	return false
+}
 
-	buffer.WriteString("http://")
-	buffer.WriteString(*domain)
-	buffer.WriteString(uri1)
-
-	go func(url string) {
-		var url1 Result
-		url1.Message, url1.Error = cusHttpClient.Get(url)
-		url1.Message.Body.Close()
-		uri1C <- url1
-	}(buffer.String())
-
-	buffer.Reset()
-	buffer.WriteString("http://")
-	buffer.WriteString(*domain)
-	buffer.WriteString(uri2)
-
-	go func(url string) {
-		var url2 Result
-		url2.Message, url2.Error = cusHttpClient.Get(url)
-		url2.Message.Body.Close()
-		uri2C <- url2
-	}(buffer.String())
-
-	uri1Result := <-uri1C
-	if uri1Result.Error != nil {
-		errC <- fmt.Sprintf("%s '%s': (e04) Problem receiving uri1-resource: %s\n", time.Now().Format(timeLogFormat), domain, uri1Result.Error)
+// testUriAvailability tests the availability concurrently of every probe matching t.Scheme and
+// returns the per-probe results plus whether all of them succeeded. A target whose scheme has no
+// matching probes configured is itself a failure (e02) rather than a silent OK, since that's the
+// one case (e.g. an https-only vhost probed by an http-only config) the tool exists to catch. One
+// target can otherwise generate one DomainFailed report per failing probe. Every probe is dialed
+// against the already-selected
+// address and t.Port so the connection actually exercises the address RFC 6724 ordering picked
+// and the port nginx binds this vhost to, while the hostname is kept for the Host header and,
+// for https probes, for SNI. httpClient and httpsClient are shared across an entire worker's
+// targets. ctx is the caller's per-domain budget; every probe's own timeout nests inside it.
+func testUriAvailability(ctx context.Context, cfg *config.Config, t target, ip net.IP, httpClient, httpsClient *http.Client, rep reporter.Reporter, errCount *uint64) ([]reporter.ProbeResult, bool) {
+
+	label := fmt.Sprintf("%s:%d", t.Host, t.Port)
+	probes := probesForScheme(cfg.Probes, t.Scheme)
+	if len(probes) == 0 {
+		atomic.AddUint64(errCount, 1)
+		rep.DomainFailed(label, "", "e02", fmt.Errorf("no probes configured for scheme %q", t.Scheme))
+		return nil, false
 	}
 
-	uri2Result := <-uri2C
-	if uri2Result.Error != nil {
-		errC <- fmt.Sprintf("%s '%s': (e05) Problem receiving uri2-resource: %s\n", time.Now().Format(timeLogFormat), domain, uri2Result.Error)
+	type result struct {
+		probe       config.Probe
+		probeResult reporter.ProbeResult
+		err         error
+		isTLSVerify bool
 	}
 
-	return uri1Result.Error == nil && uri2Result.Error == nil
-}
+	resultC := make(chan result, len(probes))
+
+	for _, probe := range probes {
+		go func(probe config.Probe) {
+			client := httpClient
+			if probe.Scheme == "https" {
+				client = httpsClient
+			}
 
-// handleMainLog reads messages from logC and writes to mainLogFile. After closing logC handleMainLog will
-// append the last status message with the number of "ok" domains to the mainLogFile
-func handleMainLog(mainLogFile *string, logC <-chan string, logFinishedC chan<- struct{}) {
+			probeCtx, cancel := context.WithTimeout(
+				contextWithDialTarget(ctx, dialTarget{ip: ip, serverName: t.Host}),
+				time.Duration(probe.TimeoutSeconds)*time.Second,
+			)
+			defer cancel()
+
+			url := fmt.Sprintf("%s://%s:%d%s", probe.Scheme, t.Host, t.Port, probe.Path)
+			req, err := http.NewRequestWithContext(probeCtx, probe.Method, url, nil)
+			if err != nil {
+				resultC <- result{probe: probe, err: err}
+				return
+			}
+			if probe.Host != "" {
+				req.Host = probe.Host
+			}
 
-	// We start at -5 because 5 default status messages are processed every run
-	okCount := -5
+			requestStart := time.Now()
+			resp, err := client.Do(req)
+			if err != nil {
+				resultC <- result{probe: probe, err: err, isTLSVerify: isCertVerificationError(err)}
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				resultC <- result{probe: probe, err: fmt.Errorf("reading response body: %w", err)}
+				return
+			}
 
-	f, err := os.OpenFile(*mainLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(fmt.Sprintf("Error opening %s for writing.\n", *mainLogFile))
+			pr := reporter.ProbeResult{
+				Probe:      probe.Name,
+				IP:         ip.String(),
+				StatusCode: resp.StatusCode,
+				Latency:    time.Since(requestStart),
+			}
+			if probe.Scheme == "https" && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+				cert := resp.TLS.PeerCertificates[0]
+				pr.CertExpirySeconds = time.Until(cert.NotAfter).Seconds()
+				pr.Warning = certExpiryWarning(cert, cfg.CertExpiryWarningDays)
+			}
+
+			probeErr := checkExpectedStatus(resp.StatusCode, probe.ExpectStatus)
+			if probeErr == nil {
+				probeErr = checkBodyMatch(body, probe)
+			}
+
+			resultC <- result{probe: probe, probeResult: pr, err: probeErr}
+		}(probe)
 	}
 
-	for msg := range logC {
-		_, err = f.WriteString(msg)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing to file %s\n", *mainLogFile)
+	allOK := true
+	results := make([]reporter.ProbeResult, 0, len(probes))
+	for i := 0; i < len(probes); i++ {
+		res := <-resultC
+		if res.err != nil {
+			allOK = false
+			atomic.AddUint64(errCount, 1)
+			if res.isTLSVerify {
+				rep.DomainFailed(label, res.probe.Name, "e06", fmt.Errorf("TLS verification failed for %s-resource: %w", res.probe.Name, res.err))
+			} else {
+				code := fmt.Sprintf("e%02d", probeErrorCodeBase+indexOfProbe(cfg.Probes, res.probe))
+				rep.DomainFailed(label, res.probe.Name, code, fmt.Errorf("problem receiving %s-resource: %w", res.probe.Name, res.err))
+			}
+			continue
 		}
-		okCount++
+		results = append(results, res.probeResult)
 	}
 
-	_, err = f.WriteString(fmt.Sprintf("\t\t    --> Ok:\t  %d\n", okCount))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing to file %s\n", *mainLogFile)
+	return results, allOK
+}
+
+// probesForScheme returns the probes configured for scheme, preserving cfg.Probes order.
+func probesForScheme(probes []config.Probe, scheme string) []config.Probe {
+	var filtered []config.Probe
+	for _, p := range probes {
+		if p.Scheme == scheme {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// certExpiryWarning returns a human-readable warning if cert's NotAfter falls within
+// warnWithinDays of now, or "" otherwise.
+func certExpiryWarning(cert *x509.Certificate, warnWithinDays int) string {
+	remaining := time.Until(cert.NotAfter)
+	if remaining > time.Duration(warnWithinDays)*24*time.Hour {
+		return ""
 	}
+	return fmt.Sprintf("certificate expires in %s (at %s)", remaining.Round(time.Hour), cert.NotAfter.Format(timeLogFormat))
+}
 
-	f.Close()
-	close(logFinishedC)
+// checkExpectedStatus returns nil if status is one of expectStatus, otherwise an error
+// naming the unexpected status code.
+func checkExpectedStatus(status int, expectStatus []int) error {
+	for _, expected := range expectStatus {
+		if status == expected {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected status code %d", status)
 }
 
-// handleErrorLog reads error messages from errC and writes to errorLogFile
-func handleErrorLog(errorLogFile *string, errCount *uint16, errC <-chan string, errLogFinishedC chan<- struct{}) {
-	f, err := os.OpenFile(*errorLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(fmt.Sprintf("Error opening %s for writing.\n", *errorLogFile))
+// checkBodyMatch returns nil if probe has no BodyMatch configured, or if body
+// contains (or, when probe.BodyRegex is set, matches) it, otherwise an error
+// describing what was expected.
+func checkBodyMatch(body []byte, probe config.Probe) error {
+	if probe.BodyMatch == "" {
+		return nil
 	}
 
-	for msg := range errC {
-		_, err = f.WriteString(msg)
+	if probe.BodyRegex {
+		re, err := regexp.Compile(probe.BodyMatch)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing to file %s\n", *errorLogFile)
+			return fmt.Errorf("invalid body_regex %q: %w", probe.BodyMatch, err)
 		}
-		*errCount++
+		if !re.Match(body) {
+			return fmt.Errorf("response body does not match regex %q", probe.BodyMatch)
+		}
+		return nil
+	}
+
+	if !bytes.Contains(body, []byte(probe.BodyMatch)) {
+		return fmt.Errorf("response body does not contain %q", probe.BodyMatch)
 	}
+	return nil
+}
 
-	f.Close()
-	close(errLogFinishedC)
+// indexOfProbe returns the position of probe within probes, used to derive a stable,
+// per-probe error code. Returns 0 if probe isn't found.
+func indexOfProbe(probes []config.Probe, probe config.Probe) int {
+	for i := range probes {
+		if probes[i].Name == probe.Name {
+			return i
+		}
+	}
+	return 0
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/Jukla/webServerCheck/config"
+)
+
+func TestProbesForScheme(t *testing.T) {
+	probes := []config.Probe{
+		{Name: "root", Scheme: "http"},
+		{Name: "health-https", Scheme: "https"},
+		{Name: "api", Scheme: "http"},
+	}
+
+	http := probesForScheme(probes, "http")
+	if len(http) != 2 || http[0].Name != "root" || http[1].Name != "api" {
+		t.Errorf("probesForScheme(http) = %+v, want [root api] in order", http)
+	}
+
+	if ftp := probesForScheme(probes, "ftp"); len(ftp) != 0 {
+		t.Errorf("probesForScheme(ftp) = %+v, want no matches", ftp)
+	}
+}
+
+func TestCheckExpectedStatus(t *testing.T) {
+	tests := []struct {
+		status       int
+		expectStatus []int
+		wantErr      bool
+	}{
+		{status: 200, expectStatus: []int{200}, wantErr: false},
+		{status: 204, expectStatus: []int{200, 204}, wantErr: false},
+		{status: 500, expectStatus: []int{200}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		err := checkExpectedStatus(tc.status, tc.expectStatus)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("checkExpectedStatus(%d, %v) error = %v, wantErr %v", tc.status, tc.expectStatus, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckBodyMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		probe   config.Probe
+		wantErr bool
+	}{
+		{name: "no body_match configured", body: "anything", probe: config.Probe{}, wantErr: false},
+		{name: "substring present", body: "status: ok", probe: config.Probe{BodyMatch: "status: ok"}, wantErr: false},
+		{name: "substring absent", body: "status: down", probe: config.Probe{BodyMatch: "status: ok"}, wantErr: true},
+		{name: "regex match", body: "build 1.2.3", probe: config.Probe{BodyMatch: `build \d+\.\d+\.\d+`, BodyRegex: true}, wantErr: false},
+		{name: "regex no match", body: "build unknown", probe: config.Probe{BodyMatch: `build \d+\.\d+\.\d+`, BodyRegex: true}, wantErr: true},
+		{name: "invalid regex", body: "anything", probe: config.Probe{BodyMatch: `(`, BodyRegex: true}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		err := checkBodyMatch([]byte(tc.body), tc.probe)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: checkBodyMatch error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCertExpiryWarning(t *testing.T) {
+	cert := &x509.Certificate{NotAfter: time.Now().Add(72 * time.Hour)}
+	if got := certExpiryWarning(cert, 14); got == "" {
+		t.Error("certExpiryWarning returned \"\" for a certificate expiring within the warning window")
+	}
+
+	cert.NotAfter = time.Now().Add(60 * 24 * time.Hour)
+	if got := certExpiryWarning(cert, 14); got != "" {
+		t.Errorf("certExpiryWarning = %q, want \"\" for a certificate far from expiry", got)
+	}
+}
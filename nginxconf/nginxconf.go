@@ -0,0 +1,269 @@
+// Package nginxconf parses nginx configuration files into the server blocks
+// they define. Unlike a single regex over server_name lines, it tokenizes
+// the file with brace-aware parsing so it can follow include directives
+// (including glob patterns such as conf.d/*.conf), collect every server_name
+// on a line, and read the listen directives a server block binds to.
+package nginxconf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Listen describes one "listen" directive of a server block.
+type Listen struct {
+	// Port is the TCP port nginx listens on, defaulting to 80 when a
+	// "listen" directive omits it. A server block with no listen directive
+	// at all has no Listen entries.
+	Port int
+
+	// SSL is true when the directive carries the "ssl" parameter.
+	SSL bool
+
+	// DefaultServer is true when the directive carries the
+	// "default_server" parameter.
+	DefaultServer bool
+}
+
+// Server is one "server { ... }" block: the hostnames it answers to and the
+// ports/schemes it's bound to.
+type Server struct {
+	Names   []string
+	Listens []Listen
+}
+
+// Parse reads the nginx configuration rooted at path, following any
+// "include" directives relative to the file that contains them (glob
+// patterns are expanded and visited in sorted order, matching nginx's own
+// behavior), and returns every server block it finds.
+func Parse(path string) ([]Server, error) {
+	tokens, err := loadTokens(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseServers(tokens)
+}
+
+// loadTokens tokenizes the file at path and inlines every "include pattern;"
+// statement it finds with the tokens of the files that pattern matches, so
+// that brace matching works across file boundaries as if everything had been
+// pasted into one file.
+func loadTokens(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	raw := tokenize(data)
+
+	var out []string
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != "include" || i+2 >= len(raw) || raw[i+2] != ";" {
+			out = append(out, raw[i])
+			continue
+		}
+
+		pattern := raw[i+1]
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expanding include %q in %s: %w", pattern, path, err)
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			included, err := loadTokens(match)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, included...)
+		}
+
+		i += 2
+	}
+
+	return out, nil
+}
+
+// tokenize splits nginx config source into directive names, argument words,
+// "{", "}", and ";", stripping "#" comments.
+func tokenize(data []byte) []string {
+	var tokens []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	inComment := false
+	for _, r := range string(data) {
+		switch {
+		case inComment:
+			if r == '\n' {
+				inComment = false
+			}
+		case r == '#':
+			flush()
+			inComment = true
+		case r == '{' || r == '}' || r == ';':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// parseServers walks tokens looking for "server { ... }" blocks, descending
+// into any other block (http, events, ...) to find server blocks nested
+// inside it.
+func parseServers(tokens []string) ([]Server, error) {
+	var servers []Server
+
+	for i := 0; i < len(tokens); {
+		switch {
+		case tokens[i] == "server" && i+1 < len(tokens) && tokens[i+1] == "{":
+			srv, next, err := parseServerBlock(tokens, i+2)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, srv)
+			i = next
+
+		case tokens[i] == "{":
+			inner, next, err := collectBlock(tokens, i+1)
+			if err != nil {
+				return nil, err
+			}
+			nested, err := parseServers(inner)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, nested...)
+			i = next
+
+		default:
+			i++
+		}
+	}
+
+	return servers, nil
+}
+
+// collectBlock returns the tokens between a matching pair of braces, given
+// the index just after the opening "{", along with the index just after the
+// matching closing "}".
+func collectBlock(tokens []string, start int) ([]string, int, error) {
+	depth := 1
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return tokens[start:i], i + 1, nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("unbalanced braces")
+}
+
+// parseServerBlock reads the server_name and listen directives directly
+// inside a server block, skipping over nested blocks (location, if, ...)
+// whose contents aren't relevant here.
+func parseServerBlock(tokens []string, start int) (Server, int, error) {
+	inner, next, err := collectBlock(tokens, start)
+	if err != nil {
+		return Server{}, 0, err
+	}
+
+	var srv Server
+
+	for i := 0; i < len(inner); {
+		if inner[i] == "{" {
+			_, skipNext, err := collectBlock(inner, i+1)
+			if err != nil {
+				return Server{}, 0, err
+			}
+			i = skipNext
+			continue
+		}
+
+		stmtStart := i
+		for i < len(inner) && inner[i] != ";" && inner[i] != "{" {
+			i++
+		}
+		if i >= len(inner) {
+			break
+		}
+		if inner[i] == "{" {
+			_, skipNext, err := collectBlock(inner, i+1)
+			if err != nil {
+				return Server{}, 0, err
+			}
+			i = skipNext
+			continue
+		}
+
+		stmt := inner[stmtStart:i]
+		i++ // consume ";"
+		if len(stmt) == 0 {
+			continue
+		}
+
+		switch stmt[0] {
+		case "server_name":
+			srv.Names = append(srv.Names, stmt[1:]...)
+		case "listen":
+			srv.Listens = append(srv.Listens, parseListen(stmt[1:]))
+		}
+	}
+
+	return srv, next, nil
+}
+
+// parseListen interprets the arguments of a "listen" directive, e.g.
+// ["80"], ["[::]:80"], ["443", "ssl", "default_server"].
+func parseListen(args []string) Listen {
+	listen := Listen{Port: 80}
+	if len(args) == 0 {
+		return listen
+	}
+
+	addr := args[0]
+	if idx := strings.LastIndex(addr, ":"); idx != -1 {
+		if port, err := strconv.Atoi(addr[idx+1:]); err == nil {
+			listen.Port = port
+		}
+	} else if port, err := strconv.Atoi(addr); err == nil {
+		listen.Port = port
+	}
+
+	for _, param := range args[1:] {
+		switch param {
+		case "ssl":
+			listen.SSL = true
+		case "default_server":
+			listen.DefaultServer = true
+		}
+	}
+
+	return listen
+}
@@ -0,0 +1,42 @@
+package nginxconf
+
+import "testing"
+
+func TestParseSitesEnabledAndConfD(t *testing.T) {
+	servers, err := Parse("testdata/nginx.conf")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if len(servers) != 2 {
+		t.Fatalf("expected 2 server blocks, got %d: %+v", len(servers), servers)
+	}
+
+	def := servers[0]
+	if got, want := len(def.Names), 2; got != want {
+		t.Fatalf("default.conf server_name count = %d, want %d", got, want)
+	}
+	if def.Names[0] != "example.com" || def.Names[1] != "www.example.com" {
+		t.Errorf("default.conf server_name = %v", def.Names)
+	}
+	if len(def.Listens) != 2 || def.Listens[0].Port != 80 || def.Listens[1].Port != 80 {
+		t.Errorf("default.conf listens = %+v, want two listens on port 80", def.Listens)
+	}
+
+	secure := servers[1]
+	if len(secure.Names) != 1 || secure.Names[0] != "secure.example.com" {
+		t.Errorf("secure.conf server_name = %v", secure.Names)
+	}
+	if len(secure.Listens) != 1 {
+		t.Fatalf("secure.conf listens = %+v, want exactly one", secure.Listens)
+	}
+	if l := secure.Listens[0]; l.Port != 443 || !l.SSL || !l.DefaultServer {
+		t.Errorf("secure.conf listen = %+v, want {Port:443 SSL:true DefaultServer:true}", l)
+	}
+}
+
+func TestParseMissingFile(t *testing.T) {
+	if _, err := Parse("testdata/does-not-exist.conf"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,80 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonEvent is one line of the newline-delimited JSON log emitted by
+// JSONReporter, suitable for ingestion by Loki/ELK.
+type jsonEvent struct {
+	Timestamp         string  `json:"ts"`
+	Domain            string  `json:"domain"`
+	Code              string  `json:"code,omitempty"`
+	Probe             string  `json:"probe,omitempty"`
+	Status            int     `json:"status,omitempty"`
+	LatencyMs         float64 `json:"latency_ms,omitempty"`
+	IP                string  `json:"ip,omitempty"`
+	CertExpirySeconds float64 `json:"cert_expiry_seconds,omitempty"`
+	Err               string  `json:"err,omitempty"`
+}
+
+// JSONReporter writes one JSON object per line to path, one line per probe
+// result plus one line per domain failure.
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	f   *os.File
+}
+
+func NewJSONReporter(path string) (*JSONReporter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	return &JSONReporter{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (r *JSONReporter) DomainOK(domain string, results []ProbeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, res := range results {
+		ev := jsonEvent{
+			Timestamp:         time.Now().Format(time.RFC3339),
+			Domain:            domain,
+			Probe:             res.Probe,
+			Status:            res.StatusCode,
+			LatencyMs:         float64(res.Latency.Microseconds()) / 1000,
+			IP:                res.IP,
+			CertExpirySeconds: res.CertExpirySeconds,
+		}
+		if res.Warning != "" {
+			ev.Code = "w01"
+			ev.Err = res.Warning
+		}
+		r.enc.Encode(ev)
+	}
+}
+
+func (r *JSONReporter) DomainFailed(domain, probe, code string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.enc.Encode(jsonEvent{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Domain:    domain,
+		Probe:     probe,
+		Code:      code,
+		Err:       err.Error(),
+	})
+}
+
+func (r *JSONReporter) Finish(summary Summary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// domainProbe is the label pair (domain, probe) used as a map key for the
+// per-probe gauges below.
+type domainProbe struct {
+	domain string
+	probe  string
+}
+
+// PrometheusReporter accumulates probe outcomes in memory and, on Finish,
+// writes them out once in Prometheus text exposition format so the tool can
+// be run by node_exporter's textfile collector.
+type PrometheusReporter struct {
+	mu         sync.Mutex
+	path       string
+	success    map[domainProbe]float64
+	latency    map[domainProbe]float64
+	certExpiry map[domainProbe]float64
+	errorTotal map[string]float64
+}
+
+func NewPrometheusReporter(path string) *PrometheusReporter {
+	return &PrometheusReporter{
+		path:       path,
+		success:    make(map[domainProbe]float64),
+		latency:    make(map[domainProbe]float64),
+		certExpiry: make(map[domainProbe]float64),
+		errorTotal: make(map[string]float64),
+	}
+}
+
+func (r *PrometheusReporter) DomainOK(domain string, results []ProbeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, res := range results {
+		key := domainProbe{domain: domain, probe: res.Probe}
+		r.success[key] = 1
+		r.latency[key] = res.Latency.Seconds()
+		if res.CertExpirySeconds > 0 {
+			r.certExpiry[key] = res.CertExpirySeconds
+		}
+	}
+}
+
+func (r *PrometheusReporter) DomainFailed(domain, probe, code string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errorTotal[code]++
+	if probe != "" {
+		r.success[domainProbe{domain: domain, probe: probe}] = 0
+	}
+}
+
+func (r *PrometheusReporter) Finish(summary Summary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", r.path, err)
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, "# HELP webserver_probe_success Whether a domain's probe last succeeded (1) or not (0).")
+	fmt.Fprintln(f, "# TYPE webserver_probe_success gauge")
+	for key, v := range r.success {
+		fmt.Fprintf(f, "webserver_probe_success{domain=%q,probe=%q} %v\n", key.domain, key.probe, v)
+	}
+
+	fmt.Fprintln(f, "# HELP webserver_probe_latency_seconds Latency of a domain's last successful probe.")
+	fmt.Fprintln(f, "# TYPE webserver_probe_latency_seconds gauge")
+	for key, v := range r.latency {
+		fmt.Fprintf(f, "webserver_probe_latency_seconds{domain=%q,probe=%q} %v\n", key.domain, key.probe, v)
+	}
+
+	fmt.Fprintln(f, "# HELP webserver_probe_cert_expiry_seconds Seconds until the probed certificate expires.")
+	fmt.Fprintln(f, "# TYPE webserver_probe_cert_expiry_seconds gauge")
+	for key, v := range r.certExpiry {
+		fmt.Fprintf(f, "webserver_probe_cert_expiry_seconds{domain=%q,probe=%q} %v\n", key.domain, key.probe, v)
+	}
+
+	fmt.Fprintln(f, "# HELP webserver_probe_errors_total Count of probe failures by error code.")
+	fmt.Fprintln(f, "# TYPE webserver_probe_errors_total counter")
+	for code, v := range r.errorTotal {
+		fmt.Fprintf(f, "webserver_probe_errors_total{code=%q} %v\n", code, v)
+	}
+
+	return nil
+}
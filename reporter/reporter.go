@@ -0,0 +1,52 @@
+// Package reporter decouples webServerCheck's probe results from how they're
+// written out. It replaces the hand-formatted text lines that used to be
+// written directly to two log files from handleMainLog/handleErrorLog with a
+// Reporter interface and three implementations: plain text (the original
+// behavior), newline-delimited JSON for log ingestion, and Prometheus text
+// exposition for a textfile collector.
+package reporter
+
+import "time"
+
+// ProbeResult is the outcome of one probe run against one domain.
+type ProbeResult struct {
+	Probe      string
+	IP         string
+	StatusCode int
+	Latency    time.Duration
+
+	// CertExpirySeconds is the time until the probed certificate's NotAfter,
+	// in seconds. Zero if the probe wasn't https or returned no certificate.
+	CertExpirySeconds float64
+
+	// Warning holds a non-fatal message (e.g. "certificate expires soon")
+	// that a Reporter may surface alongside an otherwise-ok domain.
+	Warning string
+}
+
+// Summary is handed to Finish once every domain has been processed.
+type Summary struct {
+	Domains  int
+	OK       int
+	Errors   int
+	Duration time.Duration
+}
+
+// Reporter receives the outcome of every domain check. Implementations must
+// be safe for concurrent use: domainWorker calls into a Reporter from every
+// worker goroutine.
+type Reporter interface {
+	// DomainOK records that domain's probes all succeeded. results holds one
+	// entry per probe, in the order the probes completed.
+	DomainOK(domain string, results []ProbeResult)
+
+	// DomainFailed records that domain failed, tagged with the originating
+	// error code (e01, e06, ...) and the underlying error. probe names the
+	// probe that failed, or "" for failures (DNS, address selection) that
+	// happen before any probe runs.
+	DomainFailed(domain, probe, code string, err error)
+
+	// Finish is called exactly once after every domain has been processed.
+	// Implementations should flush and close whatever they hold open.
+	Finish(summary Summary) error
+}
@@ -0,0 +1,80 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// timeLogFormat matches the timestamp format webServerCheck has always used
+// in its log messages.
+const timeLogFormat = "2006-01-02 15:04:05"
+
+// TextReporter reproduces webServerCheck's original plain-text log files:
+// one line per ok/warning domain in the main log, one line per failure in
+// the error log.
+type TextReporter struct {
+	mu       sync.Mutex
+	mainFile *os.File
+	errFile  *os.File
+}
+
+// NewTextReporter opens (creating if necessary) the main and error log files
+// and writes the "Starting" banner to the main log.
+func NewTextReporter(mainLogPath, errLogPath string) (*TextReporter, error) {
+	mainFile, err := os.OpenFile(mainLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", mainLogPath, err)
+	}
+
+	errFile, err := os.OpenFile(errLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		mainFile.Close()
+		return nil, fmt.Errorf("opening %s: %w", errLogPath, err)
+	}
+
+	fmt.Fprintf(mainFile, "%s --> Starting\n", time.Now().Format(timeLogFormat))
+
+	return &TextReporter{mainFile: mainFile, errFile: errFile}, nil
+}
+
+func (r *TextReporter) DomainOK(domain string, results []ProbeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.mainFile, "%s '%s': ok\n", time.Now().Format(timeLogFormat), domain)
+	for _, res := range results {
+		if res.Warning != "" {
+			fmt.Fprintf(r.mainFile, "%s '%s': (w01) %s\n", time.Now().Format(timeLogFormat), domain, res.Warning)
+		}
+	}
+}
+
+func (r *TextReporter) DomainFailed(domain, probe, code string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if probe != "" {
+		fmt.Fprintf(r.errFile, "%s '%s' [%s]: (%s) %v\n", time.Now().Format(timeLogFormat), domain, probe, code, err)
+		return
+	}
+	fmt.Fprintf(r.errFile, "%s '%s': (%s) %v\n", time.Now().Format(timeLogFormat), domain, code, err)
+}
+
+func (r *TextReporter) Finish(summary Summary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.mainFile, "%s --> Finished\n", time.Now().Format(timeLogFormat))
+	fmt.Fprintf(r.mainFile, "\t\t    --> Duration: %s\n", summary.Duration)
+	fmt.Fprintf(r.mainFile, "\t\t    --> Domains:  %d\n", summary.Domains)
+	fmt.Fprintf(r.mainFile, "\t\t    --> Error:\t  %d\n", summary.Errors)
+	fmt.Fprintf(r.mainFile, "\t\t    --> Ok:\t  %d\n", summary.OK)
+
+	if err := r.mainFile.Close(); err != nil {
+		r.errFile.Close()
+		return err
+	}
+	return r.errFile.Close()
+}
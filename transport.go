@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// dialTarget carries the RFC 6724-selected destination address and the TLS
+// server name through to the worker-wide http.Client built by newHTTPClient
+// / newHTTPSClient, so the client can be built once per worker and reused
+// across every domain and probe it handles instead of being rebuilt per call.
+type dialTarget struct {
+	ip         net.IP
+	serverName string
+}
+
+type dialTargetKey struct{}
+
+// contextWithDialTarget attaches target to ctx for the DialContext /
+// DialTLSContext callbacks below to pick up.
+func contextWithDialTarget(ctx context.Context, target dialTarget) context.Context {
+	return context.WithValue(ctx, dialTargetKey{}, target)
+}
+
+func dialTargetFromContext(ctx context.Context) (dialTarget, bool) {
+	target, ok := ctx.Value(dialTargetKey{}).(dialTarget)
+	return target, ok
+}
+
+// newHTTPClient builds the plain-http client shared by a single domainWorker
+// across all the domains and probes it handles. Its Transport dials the
+// address carried in the request context instead of re-resolving the host,
+// so the connection always targets the address selectPreferredAddress chose.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				target, ok := dialTargetFromContext(ctx)
+				if !ok {
+					return dialer.DialContext(ctx, network, addr)
+				}
+				return dialer.DialContext(ctx, network, net.JoinHostPort(target.ip.String(), port))
+			},
+		},
+	}
+}
+
+// newHTTPSClient is the https counterpart of newHTTPClient. It performs the
+// TLS handshake itself so that the SNI server name comes from the request
+// context (the vhost's nginx server_name) rather than from the dialed IP.
+func newHTTPSClient(insecureSkipVerify bool) *http.Client {
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				target, ok := dialTargetFromContext(ctx)
+				if !ok {
+					return nil, fmt.Errorf("no dial target in context for %s", addr)
+				}
+
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+
+				rawConn, err := dialer.DialContext(ctx, network, net.JoinHostPort(target.ip.String(), port))
+				if err != nil {
+					return nil, err
+				}
+
+				tlsConn := tls.Client(rawConn, &tls.Config{
+					ServerName:         target.serverName,
+					InsecureSkipVerify: insecureSkipVerify,
+				})
+				if err := tlsConn.HandshakeContext(ctx); err != nil {
+					tlsConn.Close()
+					return nil, err
+				}
+				return tlsConn, nil
+			},
+		},
+	}
+}
+
+// isCertVerificationError reports whether err stems from hostname or chain
+// verification rather than a generic connection problem, so callers can
+// attribute it to the dedicated e06 error code.
+func isCertVerificationError(err error) bool {
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	var certErr x509.CertificateInvalidError
+	return errors.As(err, &hostErr) || errors.As(err, &authErr) || errors.As(err, &certErr)
+}